@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"io/ioutil"
 	"log"
@@ -17,56 +18,101 @@ import (
 
 // HttpJson struct
 type ArangoDB struct {
-	Urls         	[]string 			`toml:"urls"`
-	ResponseTimeout internal.Duration	`toml:"response_timeout"`
-	Username        string 				`toml:"username"`
-	Password        string 				`toml:"password"`
+	Urls            []string          `toml:"urls"`
+	ResponseTimeout internal.Duration `toml:"response_timeout"`
+	Username        string            `toml:"username"`
+	Password        string            `toml:"password"`
+	TokenTTL        internal.Duration `toml:"token_ttl"`
+
+	ClusterDiscovery bool     `toml:"cluster_discovery"`
+	ServerRoleFilter []string `toml:"server_role_filter"`
+
+	GatherCollections   bool     `toml:"gather_collections"`
+	CollectionDatabases []string `toml:"collection_databases"`
+	GatherSlowQueries   bool     `toml:"gather_slow_queries"`
+	GatherReplication   bool     `toml:"gather_replication"`
+
+	MetricsFormat string `toml:"metrics_format"`
+
+	tls.ClientConfig
 
 	client *http.Client
+
+	tokensMu sync.Mutex
+	tokens   map[string]*cachedToken
+
+	descriptionsMu sync.Mutex
+	descriptions   map[string]*cachedDescription
+
+	formatsMu sync.Mutex
+	formats   map[string]string
+}
+
+// cachedToken holds a previously obtained JWT bearer token so that
+// gatherURL doesn't have to log in again on every Gather() call.
+type cachedToken struct {
+	jwt       string
+	expiresAt time.Time
 }
 
 type LoginRequest struct {
-	Username 		string 				`json:"username"`
-	Password 		string 				`json:"password"`
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type LoginResponse struct {
-	Jwt       		string 				`json:"jwt"`
+	Jwt string `json:"jwt"`
 }
 
 type ArangoSystem struct {
-	MajorPageFaults		uint32			`json:"majorPageFaults"`
-	MinorPageFaults		uint32			`json:"minorPageFaults"`
-	NumberOfThreads		uint32			`json:"numberOfThreads"`
-	ResidentSize		float32			`json:"residentSize"`
-	SystemTime			float32			`json:"systemTime"`
-	UserTime			float32			`json:"userTime"`
-	VirtualSize			uint64			`json:"virtualSize"`
+	MajorPageFaults uint32  `json:"majorPageFaults"`
+	MinorPageFaults uint32  `json:"minorPageFaults"`
+	NumberOfThreads uint32  `json:"numberOfThreads"`
+	ResidentSize    float32 `json:"residentSize"`
+	SystemTime      float32 `json:"systemTime"`
+	UserTime        float32 `json:"userTime"`
+	VirtualSize     uint64  `json:"virtualSize"`
 }
 
-type ArangoRequestTime struct {
-	Count				uint32			`json:"requestTime"`
-	Counts				[]uint32		`json:"counts"`
-	Sum 				float32			`json:"sum"`
+type ArangoServer struct {
+	PhysicalMemory uint64  `json:"physicalMemory"`
+	Uptime         float32 `json:"uptime"`
 }
 
-type ArangoClient struct {
-	RequestTime			ArangoRequestTime	`json:"requestTime"`
+type ArangoStats struct {
+	Server ArangoServer `json:"server"`
+	System ArangoSystem `json:"system"`
 }
 
-type ArangoServer struct {
-	PhysicalMemory 		uint64			`json:"physicalMemory"`
-	Uptime 				float32			`json:"uptime"`
+// ClusterHealthResponse is the response of /_admin/cluster/health.
+type ClusterHealthResponse struct {
+	Health map[string]ClusterHealthServer `json:"Health"`
 }
 
-type ArangoStats struct  {
-	Client 				ArangoClient	`json:"client"`
-	Server 				ArangoServer	`json:"server"`
-	System 				ArangoSystem	`json:"system"`
+type ClusterHealthServer struct {
+	Endpoint  string `json:"Endpoint"`
+	Role      string `json:"Role"`
+	ShortName string `json:"ShortName"`
+	Status    string `json:"Status"`
+}
+
+// clusterServer is a single discovered member of the cluster, resolved to
+// a URL that can be queried directly.
+type clusterServer struct {
+	id        string
+	endpoint  url.URL
+	role      string
+	shortName string
 }
 
 const loginPostfix = "/_open/auth"
 const statsPostfix = "/_admin/statistics"
+const clusterHealthPostfix = "/_admin/cluster/health"
+
+// defaultTokenTTL is used when token_ttl isn't configured. It only bounds
+// how long a cached JWT is trusted without a 401 from the server; it does
+// not need to match the server's actual token expiry.
+const defaultTokenTTL = 5 * time.Minute
 
 const sampleConfig = `
   ## An array of urls endpoints to get results from
@@ -77,6 +123,48 @@ const sampleConfig = `
 
   username = "root"
   password = "root"
+
+  ## How long a cached JWT bearer token is reused before logging in again.
+  ## The token is also refreshed early if the server responds with 401.
+  # token_ttl = "5m"
+
+  ## Query /_admin/cluster/health on each url above and fan out metric
+  ## collection to every discovered Coordinator/DBServer/Agent instead of
+  ## only querying the configured urls directly.
+  # cluster_discovery = false
+
+  ## Only collect from servers whose cluster role matches one of these
+  ## (Coordinator, DBServer, Agent). Leave empty to collect from all roles.
+  # server_role_filter = []
+
+  ## Gather per-collection document/index/cache figures from
+  ## /_api/collection and /_api/collection/{name}/figures.
+  # gather_collections = false
+
+  ## Databases to gather collection figures from. Use "*" to discover and
+  ## gather from every database on the server.
+  # collection_databases = ["_system"]
+
+  ## Gather currently running and recent slow AQL queries from
+  ## /_api/query/current and /_api/query/slow.
+  # gather_slow_queries = false
+
+  ## Gather replication logger/applier state from
+  ## /_api/replication/logger-state and /_api/replication/applier-state.
+  # gather_replication = false
+
+  ## Metrics source to use: "statistics" queries /_admin/statistics as
+  ## before, "prometheus" queries the /_admin/metrics/v2 endpoint exposed
+  ## by ArangoDB 3.8+, and "auto" probes /_admin/metrics/v2 once and falls
+  ## back to "statistics" on 404.
+  # metrics_format = "statistics"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
 `
 
 func (p *ArangoDB) SampleConfig() string {
@@ -88,10 +176,14 @@ func (p *ArangoDB) Description() string {
 }
 
 func (p *ArangoDB) createHttpClient() (*http.Client, error) {
+	tlsCfg, err := p.ClientConfig.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	client := &http.Client{
 		Transport: &http.Transport{
-			DisableKeepAlives: true,
+			TLSClientConfig: tlsCfg,
 		},
 		Timeout: p.ResponseTimeout.Duration,
 	}
@@ -99,52 +191,241 @@ func (p *ArangoDB) createHttpClient() (*http.Client, error) {
 	return client, nil
 }
 
-func (p *ArangoDB) gatherURL(u url.URL, acc telegraf.Accumulator) error {
-
-	// first get the bearer token by logging in
+// login exchanges the configured username/password for a fresh JWT bearer
+// token from the given base URL.
+func (p *ArangoDB) login(u url.URL) (string, error) {
 	loginBody := &LoginRequest{Username: p.Username, Password: p.Password}
 	loginBodyJson, err := json.Marshal(loginBody)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	loginReq, err := http.NewRequest("POST", u.String() + loginPostfix, bytes.NewBuffer(loginBodyJson))
+	loginReq, err := http.NewRequest("POST", u.String()+loginPostfix, bytes.NewBuffer(loginBodyJson))
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	tokenRequest, err := p.client.Do(loginReq)
 	if err != nil {
-		return fmt.Errorf("error making HTTP Login request to %s: %s", u, err)
+		return "", fmt.Errorf("error making HTTP Login request to %s: %s", u, err)
 	}
 	defer tokenRequest.Body.Close()
 
 	body, err := ioutil.ReadAll(tokenRequest.Body)
 	if err != nil {
-		return fmt.Errorf("error reading token body: %s", err)
+		return "", fmt.Errorf("error reading token body: %s", err)
 	}
 
 	jwtToken := LoginResponse{}
-	err = json.Unmarshal(body, &jwtToken)
+	if err := json.Unmarshal(body, &jwtToken); err != nil {
+		return "", err
+	}
+
+	return jwtToken.Jwt, nil
+}
+
+// token returns a cached, still-valid JWT for u, logging in only when the
+// cache is empty or has expired.
+func (p *ArangoDB) token(u url.URL) (string, error) {
+	key := u.String()
+
+	p.tokensMu.Lock()
+	cached, ok := p.tokens[key]
+	p.tokensMu.Unlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.jwt, nil
+	}
+
+	return p.refreshToken(u)
+}
 
+// refreshToken forces a new login for u and updates the cache, regardless
+// of whether a cached token is still considered valid.
+func (p *ArangoDB) refreshToken(u url.URL) (string, error) {
+	jwt, err := p.login(u)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	ttl := p.TokenTTL.Duration
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+
+	p.tokensMu.Lock()
+	if p.tokens == nil {
+		p.tokens = make(map[string]*cachedToken)
 	}
+	p.tokens[u.String()] = &cachedToken{jwt: jwt, expiresAt: time.Now().Add(ttl)}
+	p.tokensMu.Unlock()
+
+	return jwt, nil
+}
 
+// authedRequest builds a GET request against u+postfix, attaching the
+// cached bearer token for u and refreshing it once on a 401.
+func (p *ArangoDB) authedRequest(u url.URL, postfix string) (*http.Response, error) {
+	jwtToken, err := p.token(u)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String()+postfix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwtToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making HTTP request to %s: %s", u, err)
+	}
 
-	// at this point we've got a bearer token, and can use that to log in. Simple get
-	// so ignore the error
-	statsReq, _ := http.NewRequest("GET", u.String() + statsPostfix, nil)
-	statsReq.Header.Set("Authorization", "Bearer " + string(jwtToken.Jwt))
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
 
-	statsResponse, _ := p.client.Do(statsReq)
+		jwtToken, err = p.refreshToken(u)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err = http.NewRequest("GET", u.String()+postfix, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+jwtToken)
+
+		resp, err = p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making HTTP request to %s: %s", u, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// discoverCluster queries /_admin/cluster/health on the given coordinator
+// and returns every member matching ServerRoleFilter (or all members, if
+// the filter is empty).
+func (p *ArangoDB) discoverCluster(u url.URL) ([]clusterServer, error) {
+	resp, err := p.authedRequest(u, clusterHealthPostfix)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cluster health body: %s", err)
+	}
+
+	health := ClusterHealthResponse{}
+	if err := json.Unmarshal(body, &health); err != nil {
+		return nil, err
+	}
+
+	servers := make([]clusterServer, 0, len(health.Health))
+	for id, member := range health.Health {
+		if len(p.ServerRoleFilter) > 0 && !roleMatches(p.ServerRoleFilter, member.Role) {
+			continue
+		}
+
+		endpoint, err := clusterEndpointURL(u, member.Endpoint)
+		if err != nil {
+			log.Printf("arangodb: could not parse cluster endpoint %q for %s, skipping. Error: %s", member.Endpoint, id, err)
+			continue
+		}
+
+		servers = append(servers, clusterServer{
+			id:        id,
+			endpoint:  endpoint,
+			role:      member.Role,
+			shortName: member.ShortName,
+		})
+	}
+
+	return servers, nil
+}
+
+func roleMatches(filter []string, role string) bool {
+	for _, f := range filter {
+		if f == role {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterEndpointURL turns an ArangoDB cluster endpoint such as
+// "tcp://10.0.0.1:8529" or "ssl://10.0.0.1:8529" into an http(s) URL,
+// reusing the scheme of the coordinator it was discovered through.
+func clusterEndpointURL(coordinator url.URL, endpoint string) (url.URL, error) {
+	raw, err := url.Parse(endpoint)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	scheme := coordinator.Scheme
+	switch raw.Scheme {
+	case "ssl":
+		scheme = "https"
+	case "tcp":
+		scheme = "http"
+	}
+
+	return url.URL{Scheme: scheme, Host: raw.Host}, nil
+}
+
+// gatherCluster discovers the members of the cluster reachable through the
+// given coordinator and gathers metrics from each of them concurrently.
+func (p *ArangoDB) gatherCluster(coordinator url.URL, acc telegraf.Accumulator) error {
+	servers, err := p.discoverCluster(coordinator)
+	if err != nil {
+		return fmt.Errorf("error discovering cluster via %s: %s", coordinator, err)
+	}
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server clusterServer) {
+			defer wg.Done()
+
+			tags := map[string]string{
+				"role":       server.role,
+				"server_id":  server.id,
+				"short_name": server.shortName,
+			}
+			acc.AddError(p.gatherURL(server.endpoint, acc, tags))
+		}(server)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (p *ArangoDB) gatherURL(u url.URL, acc telegraf.Accumulator, extraTags map[string]string) error {
+	tags := make(map[string]string)
+	tags["url"] = u.String()
+	for k, v := range extraTags {
+		tags[k] = v
+	}
+
+	if p.effectiveMetricsFormat(u) == metricsFormatPrometheus {
+		return p.gatherPrometheusMetrics(u, tags, acc)
+	}
+
+	return p.gatherStatistics(u, tags, acc)
+}
+
+func (p *ArangoDB) gatherStatistics(u url.URL, tags map[string]string, acc telegraf.Accumulator) error {
+	statsResponse, err := p.authedRequest(u, statsPostfix)
 	if err != nil {
 		return fmt.Errorf("error making HTTP Stats request to %s: %s", u, err)
 	}
 	defer statsResponse.Body.Close()
 
-	body, err = ioutil.ReadAll(statsResponse.Body)
+	body, err := ioutil.ReadAll(statsResponse.Body)
 	if err != nil {
 		return fmt.Errorf("error reading stats body: %s", err)
 	}
@@ -156,10 +437,6 @@ func (p *ArangoDB) gatherURL(u url.URL, acc telegraf.Accumulator) error {
 		return err
 	}
 
-	// create the arguments for the accumulator
-	tags := make(map[string]string)
-	tags["url"] = u.String()
-
 	systemFields := make(map[string]interface{})
 	systemFields["majorPageFaults"] = stats.System.MajorPageFaults
 	systemFields["minorPageFaults"] = stats.System.MinorPageFaults
@@ -173,20 +450,13 @@ func (p *ArangoDB) gatherURL(u url.URL, acc telegraf.Accumulator) error {
 	serverFields["physicalMemory"] = stats.Server.PhysicalMemory
 	serverFields["uptime"] = stats.Server.Uptime
 
-	clientFields := make(map[string]interface{})
-	clientFields["req_0.01"] = stats.Client.RequestTime.Counts[0]
-	clientFields["req_0.05"] = stats.Client.RequestTime.Counts[1]
-	clientFields["req_0.1"] = stats.Client.RequestTime.Counts[2]
-	clientFields["req_0.2"] = stats.Client.RequestTime.Counts[3]
-	clientFields["req_0.5"] = stats.Client.RequestTime.Counts[4]
-	clientFields["req_1"] = stats.Client.RequestTime.Counts[5]
-	clientFields["count"] = stats.Client.RequestTime.Count
-	clientFields["sum"] = stats.Client.RequestTime.Sum
-
 	// add all th fields
 	acc.AddFields("arangodb_system", systemFields, tags)
 	acc.AddFields("arangodb_server", serverFields, tags)
-	acc.AddFields("arangodb_client", clientFields, tags)
+
+	if err := p.gatherHistograms(u, stats.Server.Uptime, body, tags, acc); err != nil {
+		acc.AddError(fmt.Errorf("error gathering histograms for %s: %s", u, err))
+	}
 
 	return nil
 }
@@ -217,7 +487,14 @@ func (p *ArangoDB) Gather(acc telegraf.Accumulator) error {
 		wg.Add(1)
 		go func(serviceURL url.URL) {
 			defer wg.Done()
-			acc.AddError(p.gatherURL(serviceURL, acc))
+
+			if p.ClusterDiscovery {
+				acc.AddError(p.gatherCluster(serviceURL, acc))
+			} else {
+				acc.AddError(p.gatherURL(serviceURL, acc, nil))
+			}
+
+			acc.AddError(p.gatherDatabases(serviceURL, acc))
 		}(URL)
 	}
 
@@ -225,7 +502,6 @@ func (p *ArangoDB) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-
 func init() {
 	inputs.Add("arangoDB", func() telegraf.Input {
 		return &ArangoDB{ResponseTimeout: internal.Duration{Duration: time.Second * 3}}
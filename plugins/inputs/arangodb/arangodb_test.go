@@ -0,0 +1,94 @@
+package arangodb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestArangoDB(t *testing.T) *ArangoDB {
+	p := &ArangoDB{
+		Username:        "root",
+		Password:        "root",
+		TokenTTL:        internal.Duration{Duration: time.Minute},
+		ResponseTimeout: internal.Duration{Duration: 3 * time.Second},
+	}
+
+	client, err := p.createHttpClient()
+	require.NoError(t, err)
+	p.client = client
+
+	return p
+}
+
+func TestAuthedRequestReusesCachedToken(t *testing.T) {
+	var logins int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(loginPostfix, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		json.NewEncoder(w).Encode(LoginResponse{Jwt: "good-token"})
+	})
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := newTestArangoDB(t)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		resp, err := p.authedRequest(*u, "/ping")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&logins), "cached JWT should be reused instead of logging in again")
+}
+
+func TestAuthedRequestRefreshesTokenOn401(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(loginPostfix, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LoginResponse{Jwt: "fresh-token"})
+	})
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := newTestArangoDB(t)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// seed the cache with a token the server will reject, simulating one
+	// that expired or was revoked server-side.
+	p.tokens = map[string]*cachedToken{
+		u.String(): {jwt: "stale-token", expiresAt: time.Now().Add(time.Minute)},
+	}
+
+	resp, err := p.authedRequest(*u, "/ping")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode, "authedRequest should refresh the token once and retry on 401")
+	require.Equal(t, "fresh-token", p.tokens[u.String()].jwt)
+}
@@ -0,0 +1,32 @@
+package arangodb
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterEndpointURLSchemeMapping(t *testing.T) {
+	coordinator := url.URL{Scheme: "https", Host: "coordinator:8529"}
+
+	cases := []struct {
+		name     string
+		endpoint string
+		scheme   string
+		host     string
+	}{
+		{"tcp endpoint maps to http", "tcp://10.0.0.1:8529", "http", "10.0.0.1:8529"},
+		{"ssl endpoint maps to https", "ssl://10.0.0.2:8529", "https", "10.0.0.2:8529"},
+		{"unrecognized scheme falls back to the coordinator's scheme", "unix:///var/run/arangodb.sock", "https", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := clusterEndpointURL(coordinator, tc.endpoint)
+			require.NoError(t, err)
+			require.Equal(t, tc.scheme, got.Scheme)
+			require.Equal(t, tc.host, got.Host)
+		})
+	}
+}
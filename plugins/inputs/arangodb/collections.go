@@ -0,0 +1,253 @@
+package arangodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/influxdata/telegraf"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+const collectionListPostfix = "/_api/collection"
+const collectionShardsQuery = "/shards?details=true"
+
+// CollectionListResponse is the response of /_api/collection.
+type CollectionListResponse struct {
+	Result []CollectionInfo `json:"result"`
+}
+
+type CollectionInfo struct {
+	Name     string `json:"name"`
+	IsSystem bool   `json:"isSystem"`
+}
+
+// CollectionFiguresResponse is the response of
+// /_api/collection/{name}/figures.
+type CollectionFiguresResponse struct {
+	Count   int64             `json:"count"`
+	Figures CollectionFigures `json:"figures"`
+}
+
+type CollectionFigures struct {
+	Indexes struct {
+		Count int64 `json:"count"`
+		Size  int64 `json:"size"`
+	} `json:"indexes"`
+	DocumentsSize int64 `json:"documentsSize"`
+	JournalSize   int64 `json:"journalSize"`
+	CacheInUse    bool  `json:"cacheInUse"`
+	CacheSize     int64 `json:"cacheSize"`
+	CacheUsage    int64 `json:"cacheUsage"`
+}
+
+// CollectionShardsResponse is the response of
+// /_api/collection/{name}/shards?details=true: shard id -> the DBServer
+// ids holding it, leader first.
+type CollectionShardsResponse struct {
+	Shards map[string][]string `json:"shards"`
+}
+
+// gatherCollections lists the non-system collections of db and emits
+// figures for each of them, plus a per-shard breakdown when the server is
+// a cluster deployment, as arangodb_collection measurements.
+func (p *ArangoDB) gatherCollections(u url.URL, db string, acc telegraf.Accumulator) error {
+	collections, err := p.listCollections(u, db)
+	if err != nil {
+		return fmt.Errorf("error listing collections for %s/%s: %s", u, db, err)
+	}
+
+	// Resolved lazily, the first time a collection turns out to have shards,
+	// and reused for the rest of the call once resolved; a failed resolution
+	// is tried again for the next sharded collection. A single-server
+	// deployment never needs it.
+	var endpointByID map[string]url.URL
+	var endpointsResolved bool
+
+	for _, collection := range collections {
+		if collection.IsSystem {
+			continue
+		}
+
+		if err := p.gatherCollectionFigures(u, db, collection.Name, acc); err != nil {
+			acc.AddError(fmt.Errorf("error gathering figures for %s/%s/%s: %s", u, db, collection.Name, err))
+			continue
+		}
+
+		shards, err := p.collectionShards(u, db, collection.Name)
+		if err != nil {
+			acc.AddError(fmt.Errorf("error listing shards for %s/%s/%s: %s", u, db, collection.Name, err))
+			continue
+		}
+		if len(shards) == 0 {
+			continue
+		}
+
+		if !endpointsResolved {
+			endpointByID, err = p.clusterEndpointsByID(u)
+			if err != nil {
+				acc.AddError(fmt.Errorf("error resolving DBServer endpoints for %s: %s", u, err))
+				continue
+			}
+			endpointsResolved = true
+		}
+
+		p.gatherShardFigures(u, db, collection.Name, shards, endpointByID, acc)
+	}
+
+	return nil
+}
+
+// clusterEndpointsByID resolves the cluster members reachable through u
+// into a server-id -> endpoint map.
+func (p *ArangoDB) clusterEndpointsByID(u url.URL) (map[string]url.URL, error) {
+	servers, err := p.discoverCluster(u)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointByID := make(map[string]url.URL, len(servers))
+	for _, server := range servers {
+		endpointByID[server.id] = server.endpoint
+	}
+
+	return endpointByID, nil
+}
+
+func (p *ArangoDB) listCollections(u url.URL, db string) ([]CollectionInfo, error) {
+	resp, err := p.authedRequest(u, dbPath(db, collectionListPostfix))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading collection list body: %s", err)
+	}
+
+	list := CollectionListResponse{}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Result, nil
+}
+
+// gatherCollectionFigures emits the collection-wide figures (aggregated
+// across all shards by the coordinator). The "shard" tag is left empty to
+// distinguish this aggregate row from the per-shard rows gatherShardFigures
+// adds on cluster deployments.
+func (p *ArangoDB) gatherCollectionFigures(u url.URL, db, collection string, acc telegraf.Accumulator) error {
+	postfix := dbPath(db, fmt.Sprintf("%s/%s/figures", collectionListPostfix, collection))
+	figures, err := p.fetchCollectionFigures(u, postfix)
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{
+		"url":        u.String(),
+		"database":   db,
+		"collection": collection,
+		"shard":      "",
+	}
+
+	emitCollectionFigures(figures, tags, acc)
+
+	return nil
+}
+
+// gatherShardFigures gathers figures for every shard directly from the
+// DBServer holding its leader (resolved via endpointByID), so each result
+// reflects that shard only. endpointByID is resolved once per
+// gatherCollections pass and passed in to avoid rediscovering the cluster
+// topology for every collection; shards is the map for this collection.
+func (p *ArangoDB) gatherShardFigures(u url.URL, db, collection string, shards map[string][]string, endpointByID map[string]url.URL, acc telegraf.Accumulator) {
+	for shardID, replicas := range shards {
+		if len(replicas) == 0 {
+			continue
+		}
+
+		leaderEndpoint, ok := endpointByID[replicas[0]]
+		if !ok {
+			acc.AddError(fmt.Errorf("error resolving DBServer %s holding shard %s of %s/%s", replicas[0], shardID, db, collection))
+			continue
+		}
+
+		postfix := dbPath(db, fmt.Sprintf("%s/%s/figures", collectionListPostfix, shardID))
+		figures, err := p.fetchCollectionFigures(leaderEndpoint, postfix)
+		if err != nil {
+			acc.AddError(fmt.Errorf("error gathering figures for shard %s of %s/%s: %s", shardID, db, collection, err))
+			continue
+		}
+
+		tags := map[string]string{
+			"url":        u.String(),
+			"database":   db,
+			"collection": collection,
+			"shard":      shardID,
+		}
+
+		emitCollectionFigures(figures, tags, acc)
+	}
+}
+
+// collectionShards returns the shard-to-DBServer map for collection, or nil
+// if the server doesn't expose shard information (e.g. single-server mode).
+func (p *ArangoDB) collectionShards(u url.URL, db, collection string) (map[string][]string, error) {
+	postfix := dbPath(db, fmt.Sprintf("%s/%s%s", collectionListPostfix, collection, collectionShardsQuery))
+	resp, err := p.authedRequest(u, postfix)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading collection shards body: %s", err)
+	}
+
+	shards := CollectionShardsResponse{}
+	if err := json.Unmarshal(body, &shards); err != nil {
+		return nil, err
+	}
+
+	return shards.Shards, nil
+}
+
+func (p *ArangoDB) fetchCollectionFigures(u url.URL, postfix string) (CollectionFiguresResponse, error) {
+	figures := CollectionFiguresResponse{}
+
+	resp, err := p.authedRequest(u, postfix)
+	if err != nil {
+		return figures, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return figures, fmt.Errorf("error reading collection figures body: %s", err)
+	}
+
+	err = json.Unmarshal(body, &figures)
+	return figures, err
+}
+
+func emitCollectionFigures(figures CollectionFiguresResponse, tags map[string]string, acc telegraf.Accumulator) {
+	fields := map[string]interface{}{
+		"document_count": figures.Count,
+		"indexes_count":  figures.Figures.Indexes.Count,
+		"indexes_size":   figures.Figures.Indexes.Size,
+		"documents_size": figures.Figures.DocumentsSize,
+		"journal_size":   figures.Figures.JournalSize,
+		"cache_in_use":   figures.Figures.CacheInUse,
+		"cache_size":     figures.Figures.CacheSize,
+		"cache_usage":    figures.Figures.CacheUsage,
+	}
+
+	acc.AddFields("arangodb_collection", fields, tags)
+}
@@ -0,0 +1,93 @@
+package arangodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/influxdata/telegraf"
+	"io/ioutil"
+	"net/url"
+)
+
+const databaseListPostfix = "/_api/database"
+
+// allDatabasesSentinel requests discovery of every database on the server,
+// instead of a fixed list.
+const allDatabasesSentinel = "*"
+
+// DatabaseListResponse is the response of /_api/database.
+type DatabaseListResponse struct {
+	Result []string `json:"result"`
+}
+
+// dbPath builds the request path for path scoped to database db, suitable
+// for passing to authedRequest as the postfix.
+func dbPath(db, path string) string {
+	return fmt.Sprintf("/_db/%s%s", db, path)
+}
+
+// resolveDatabases expands CollectionDatabases, querying /_api/database for
+// the full list when "*" is configured.
+func (p *ArangoDB) resolveDatabases(u url.URL) ([]string, error) {
+	databases := p.CollectionDatabases
+	if len(databases) == 0 {
+		databases = []string{"_system"}
+	}
+
+	discover := false
+	for _, db := range databases {
+		if db == allDatabasesSentinel {
+			discover = true
+			break
+		}
+	}
+
+	if !discover {
+		return databases, nil
+	}
+
+	resp, err := p.authedRequest(u, databaseListPostfix)
+	if err != nil {
+		return nil, fmt.Errorf("error making HTTP database list request to %s: %s", u, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading database list body: %s", err)
+	}
+
+	list := DatabaseListResponse{}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Result, nil
+}
+
+// gatherDatabases gathers the database-scoped measurements (collections,
+// AQL queries, replication state) that aren't tied to a single physical
+// server the way /_admin/statistics is.
+func (p *ArangoDB) gatherDatabases(u url.URL, acc telegraf.Accumulator) error {
+	if !p.GatherCollections && !p.GatherSlowQueries && !p.GatherReplication {
+		return nil
+	}
+
+	databases, err := p.resolveDatabases(u)
+	if err != nil {
+		return fmt.Errorf("error resolving databases for %s: %s", u, err)
+	}
+
+	for _, db := range databases {
+		if p.GatherCollections {
+			acc.AddError(p.gatherCollections(u, db, acc))
+		}
+		if p.GatherSlowQueries {
+			acc.AddError(p.gatherQueries(u, db, acc))
+		}
+		if p.GatherReplication {
+			acc.AddError(p.gatherReplication(u, db, acc))
+		}
+	}
+
+	return nil
+}
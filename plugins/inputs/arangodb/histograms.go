@@ -0,0 +1,206 @@
+package arangodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/influxdata/telegraf"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+)
+
+const statsDescriptionPostfix = "/_admin/statistics-description"
+
+// distributionType is the figure type ArangoDB uses for histogram/bucket
+// series such as client.totalTime or client.requestTime.
+const distributionType = "distribution"
+
+// StatisticsDescription is the response of /_admin/statistics-description,
+// describing the shape (including histogram bucket boundaries) of the
+// figures returned by /_admin/statistics.
+type StatisticsDescription struct {
+	Figures []StatisticsFigure `json:"figures"`
+}
+
+type StatisticsFigure struct {
+	Group      string    `json:"group"`
+	Identifier string    `json:"identifier"`
+	Type       string    `json:"type"`
+	Cuts       []float64 `json:"cuts"`
+}
+
+// cachedDescription holds a previously fetched statistics-description
+// alongside the server uptime observed at fetch time, so it can be
+// refreshed when the uptime resets (i.e. the server restarted).
+type cachedDescription struct {
+	description StatisticsDescription
+	uptime      float32
+}
+
+// distributionValue is the shape of a distribution figure inside the
+// /_admin/statistics response, e.g. client.totalTime.
+type distributionValue struct {
+	Sum    float64   `json:"sum"`
+	Count  float64   `json:"count"`
+	Counts []float64 `json:"counts"`
+}
+
+// gatherHistograms fetches (or reuses the cached) statistics-description
+// for u and emits one arangodb_client field per histogram bucket for every
+// client-group distribution figure, plus the http-group request counters.
+func (p *ArangoDB) gatherHistograms(u url.URL, uptime float32, statsBody []byte, tags map[string]string, acc telegraf.Accumulator) error {
+	description, err := p.statisticsDescription(u, uptime)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(statsBody, &raw); err != nil {
+		return err
+	}
+
+	if clientRaw, ok := raw["client"]; ok {
+		if err := p.emitDistributions(description, clientRaw, tags, acc); err != nil {
+			return err
+		}
+	}
+
+	if httpRaw, ok := raw["http"]; ok {
+		if err := emitHTTPCounters(httpRaw, tags, acc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *ArangoDB) emitDistributions(description StatisticsDescription, groupRaw json.RawMessage, tags map[string]string, acc telegraf.Accumulator) error {
+	var group map[string]json.RawMessage
+	if err := json.Unmarshal(groupRaw, &group); err != nil {
+		return err
+	}
+
+	figuresByIdentifier := make(map[string]StatisticsFigure)
+	for _, figure := range description.Figures {
+		if figure.Group == "client" && figure.Type == distributionType {
+			figuresByIdentifier[figure.Identifier] = figure
+		}
+	}
+
+	fields := make(map[string]interface{})
+	for identifier, valueRaw := range group {
+		figure, ok := figuresByIdentifier[identifier]
+		if !ok {
+			continue
+		}
+
+		value := distributionValue{}
+		if err := json.Unmarshal(valueRaw, &value); err != nil {
+			continue
+		}
+
+		fields[identifier+"_count"] = value.Count
+		fields[identifier+"_sum"] = value.Sum
+
+		// ArangoDB's counts are per-bucket, not cumulative, and include a
+		// trailing overflow bucket above the last cut. Prometheus-style
+		// "le" buckets are cumulative, so run a rolling total and emit a
+		// final +Inf bucket for the overflow count.
+		var cumulative float64
+		for i, cut := range figure.Cuts {
+			if i >= len(value.Counts) {
+				break
+			}
+			cumulative += value.Counts[i]
+			fields[identifier+"_le_"+formatCut(cut)] = cumulative
+		}
+		if len(value.Counts) > len(figure.Cuts) {
+			cumulative += value.Counts[len(figure.Cuts)]
+		}
+		fields[identifier+"_le_+Inf"] = cumulative
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields("arangodb_client", fields, tags)
+	}
+
+	return nil
+}
+
+// emitHTTPCounters emits the simple per-method request counters under the
+// "http" group (requestsGet, requestsPost, ...) as arangodb_http.
+func emitHTTPCounters(groupRaw json.RawMessage, tags map[string]string, acc telegraf.Accumulator) error {
+	var group map[string]float64
+	if err := json.Unmarshal(groupRaw, &group); err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{}, len(group))
+	for identifier, count := range group {
+		fields[identifier] = count
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields("arangodb_http", fields, tags)
+	}
+
+	return nil
+}
+
+// statisticsDescription returns the cached statistics-description for u,
+// refetching it only when uptime indicates the server has restarted since
+// the cached copy was taken.
+func (p *ArangoDB) statisticsDescription(u url.URL, uptime float32) (StatisticsDescription, error) {
+	key := u.String()
+
+	p.descriptionsMu.Lock()
+	cached, ok := p.descriptions[key]
+	p.descriptionsMu.Unlock()
+
+	if ok && uptime >= cached.uptime {
+		return cached.description, nil
+	}
+
+	description, err := p.fetchStatisticsDescription(u)
+	if err != nil {
+		if ok {
+			// keep emitting histograms with the stale description rather
+			// than dropping them entirely because of a transient error.
+			return cached.description, nil
+		}
+		return StatisticsDescription{}, err
+	}
+
+	p.descriptionsMu.Lock()
+	if p.descriptions == nil {
+		p.descriptions = make(map[string]*cachedDescription)
+	}
+	p.descriptions[key] = &cachedDescription{description: description, uptime: uptime}
+	p.descriptionsMu.Unlock()
+
+	return description, nil
+}
+
+func (p *ArangoDB) fetchStatisticsDescription(u url.URL) (StatisticsDescription, error) {
+	description := StatisticsDescription{}
+
+	resp, err := p.authedRequest(u, statsDescriptionPostfix)
+	if err != nil {
+		return description, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return description, fmt.Errorf("error reading statistics-description body: %s", err)
+	}
+
+	err = json.Unmarshal(body, &description)
+	return description, err
+}
+
+// formatCut renders a bucket boundary the same way across runs so field
+// names stay stable, e.g. 0.01 -> "0.01", 10 -> "10".
+func formatCut(cut float64) string {
+	return strconv.FormatFloat(cut, 'f', -1, 64)
+}
@@ -0,0 +1,55 @@
+package arangodb
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCut(t *testing.T) {
+	cases := map[float64]string{
+		0.01: "0.01",
+		0.1:  "0.1",
+		1:    "1",
+		10:   "10",
+		30:   "30",
+	}
+
+	for cut, want := range cases {
+		require.Equal(t, want, formatCut(cut))
+	}
+}
+
+func TestEmitDistributionsBuildsBucketFields(t *testing.T) {
+	description := StatisticsDescription{
+		Figures: []StatisticsFigure{
+			{Group: "client", Identifier: "totalTime", Type: distributionType, Cuts: []float64{0.01, 0.05, 0.1}},
+			{Group: "client", Identifier: "bytesSent", Type: "accumulated"},
+		},
+	}
+
+	// counts is per-bucket (not cumulative) and has one more entry than
+	// cuts: [0.01]=1, [0.05]=2, [0.1]=1, and a trailing overflow bucket
+	// above 0.1 of 2.
+	groupRaw := []byte(`{
+		"totalTime": {"sum": 12.5, "count": 6, "counts": [1, 2, 1, 2]},
+		"bytesSent": {"sum": 100, "count": 4}
+	}`)
+
+	acc := &testutil.Accumulator{}
+	p := &ArangoDB{}
+	err := p.emitDistributions(description, groupRaw, map[string]string{"url": "http://localhost:8529"}, acc)
+	require.NoError(t, err)
+
+	acc.AssertContainsFields(t, "arangodb_client", map[string]interface{}{
+		"totalTime_count":   float64(6),
+		"totalTime_sum":     12.5,
+		"totalTime_le_0.01": float64(1),
+		"totalTime_le_0.05": float64(3),
+		"totalTime_le_0.1":  float64(4),
+		"totalTime_le_+Inf": float64(6),
+	})
+
+	require.False(t, acc.HasField("arangodb_client", "bytesSent_count"), "non-distribution client figures should not be emitted by emitDistributions")
+}
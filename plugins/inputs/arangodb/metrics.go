@@ -0,0 +1,135 @@
+package arangodb
+
+import (
+	"fmt"
+	"github.com/influxdata/telegraf"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"net/url"
+	"strings"
+)
+
+const metricsV2Postfix = "/_admin/metrics/v2"
+
+const (
+	metricsFormatPrometheus = "prometheus"
+	metricsFormatStatistics = "statistics"
+	metricsFormatAuto       = "auto"
+)
+
+// metricNamePrefix is stripped from Prometheus metric names before they're
+// used as Telegraf field names, e.g. "arangodb_scheduler_queue_length"
+// becomes "scheduler_queue_length".
+const metricNamePrefix = "arangodb_"
+
+// effectiveMetricsFormat resolves MetricsFormat to either "prometheus" or
+// "statistics", probing the server once when set to "auto".
+func (p *ArangoDB) effectiveMetricsFormat(u url.URL) string {
+	switch p.MetricsFormat {
+	case metricsFormatPrometheus:
+		return metricsFormatPrometheus
+	case metricsFormatAuto:
+		return p.probeMetricsFormat(u)
+	default:
+		return metricsFormatStatistics
+	}
+}
+
+// probeMetricsFormat requests /_admin/metrics/v2 once per endpoint and
+// remembers whether it's present, falling back to "statistics" unless the
+// probe comes back with a 2xx (a 404 means the endpoint doesn't exist on
+// this version; any other non-2xx, e.g. 401/500, isn't proof the endpoint
+// works either, so it shouldn't be cached as "prometheus").
+func (p *ArangoDB) probeMetricsFormat(u url.URL) string {
+	key := u.String()
+
+	p.formatsMu.Lock()
+	cached, ok := p.formats[key]
+	p.formatsMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	format := metricsFormatStatistics
+	resp, err := p.authedRequest(u, metricsV2Postfix)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			format = metricsFormatPrometheus
+		}
+	}
+
+	p.formatsMu.Lock()
+	if p.formats == nil {
+		p.formats = make(map[string]string)
+	}
+	p.formats[key] = format
+	p.formatsMu.Unlock()
+
+	return format
+}
+
+// gatherPrometheusMetrics fetches /_admin/metrics/v2 and emits one
+// arangodb_metrics field set per exposed Prometheus metric, tagged with its
+// labels.
+func (p *ArangoDB) gatherPrometheusMetrics(u url.URL, tags map[string]string, acc telegraf.Accumulator) error {
+	resp, err := p.authedRequest(u, metricsV2Postfix)
+	if err != nil {
+		return fmt.Errorf("error making HTTP metrics request to %s: %s", u, err)
+	}
+	defer resp.Body.Close()
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error parsing prometheus metrics from %s: %s", u, err)
+	}
+
+	for _, family := range families {
+		emitMetricFamily(family, tags, acc)
+	}
+
+	return nil
+}
+
+func emitMetricFamily(family *dto.MetricFamily, baseTags map[string]string, acc telegraf.Accumulator) {
+	name := strings.TrimPrefix(family.GetName(), metricNamePrefix)
+
+	for _, metric := range family.GetMetric() {
+		tags := make(map[string]string, len(baseTags)+len(metric.GetLabel()))
+		for k, v := range baseTags {
+			tags[k] = v
+		}
+		for _, label := range metric.GetLabel() {
+			tags[label.GetName()] = label.GetValue()
+		}
+
+		fields := make(map[string]interface{})
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			fields[name] = metric.GetCounter().GetValue()
+		case dto.MetricType_GAUGE:
+			fields[name] = metric.GetGauge().GetValue()
+		case dto.MetricType_UNTYPED:
+			fields[name] = metric.GetUntyped().GetValue()
+		case dto.MetricType_HISTOGRAM:
+			histogram := metric.GetHistogram()
+			fields[name+"_count"] = float64(histogram.GetSampleCount())
+			fields[name+"_sum"] = histogram.GetSampleSum()
+			for _, bucket := range histogram.GetBucket() {
+				fields[name+"_le_"+formatCut(bucket.GetUpperBound())] = float64(bucket.GetCumulativeCount())
+			}
+		case dto.MetricType_SUMMARY:
+			summary := metric.GetSummary()
+			fields[name+"_count"] = float64(summary.GetSampleCount())
+			fields[name+"_sum"] = summary.GetSampleSum()
+			for _, quantile := range summary.GetQuantile() {
+				fields[name+"_quantile_"+formatCut(quantile.GetQuantile())] = quantile.GetValue()
+			}
+		default:
+			continue
+		}
+
+		acc.AddFields("arangodb_metrics", fields, tags)
+	}
+}
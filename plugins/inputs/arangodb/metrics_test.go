@@ -0,0 +1,108 @@
+package arangodb
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func strPtr(s string) *string   { return &s }
+func f64Ptr(f float64) *float64 { return &f }
+func u64Ptr(u uint64) *uint64   { return &u }
+
+func TestEmitMetricFamilyCounter(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("arangodb_scheduler_queue_length"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Metric: []*dto.Metric{
+			{
+				Label:   []*dto.LabelPair{{Name: strPtr("queue"), Value: strPtr("high")}},
+				Counter: &dto.Counter{Value: f64Ptr(42)},
+			},
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	emitMetricFamily(family, map[string]string{"url": "http://localhost:8529"}, acc)
+
+	acc.AssertContainsTaggedFields(t, "arangodb_metrics",
+		map[string]interface{}{"scheduler_queue_length": float64(42)},
+		map[string]string{"url": "http://localhost:8529", "queue": "high"},
+	)
+}
+
+func TestEmitMetricFamilyGauge(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("arangodb_memory_pool_usage"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{
+			{Gauge: &dto.Gauge{Value: f64Ptr(1024)}},
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	emitMetricFamily(family, nil, acc)
+
+	acc.AssertContainsFields(t, "arangodb_metrics", map[string]interface{}{
+		"memory_pool_usage": float64(1024),
+	})
+}
+
+func TestEmitMetricFamilyHistogram(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("arangodb_request_duration"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{
+			{
+				Histogram: &dto.Histogram{
+					SampleCount: u64Ptr(3),
+					SampleSum:   f64Ptr(1.5),
+					Bucket: []*dto.Bucket{
+						{UpperBound: f64Ptr(0.1), CumulativeCount: u64Ptr(1)},
+						{UpperBound: f64Ptr(1), CumulativeCount: u64Ptr(3)},
+					},
+				},
+			},
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	emitMetricFamily(family, nil, acc)
+
+	acc.AssertContainsFields(t, "arangodb_metrics", map[string]interface{}{
+		"request_duration_count":  float64(3),
+		"request_duration_sum":    1.5,
+		"request_duration_le_0.1": float64(1),
+		"request_duration_le_1":   float64(3),
+	})
+}
+
+func TestEmitMetricFamilySummary(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: strPtr("arangodb_agency_write_latency"),
+		Type: dto.MetricType_SUMMARY.Enum(),
+		Metric: []*dto.Metric{
+			{
+				Summary: &dto.Summary{
+					SampleCount: u64Ptr(2),
+					SampleSum:   f64Ptr(0.4),
+					Quantile: []*dto.Quantile{
+						{Quantile: f64Ptr(0.5), Value: f64Ptr(0.2)},
+						{Quantile: f64Ptr(0.99), Value: f64Ptr(0.39)},
+					},
+				},
+			},
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	emitMetricFamily(family, nil, acc)
+
+	acc.AssertContainsFields(t, "arangodb_metrics", map[string]interface{}{
+		"agency_write_latency_count":         float64(2),
+		"agency_write_latency_sum":           0.4,
+		"agency_write_latency_quantile_0.5":  0.2,
+		"agency_write_latency_quantile_0.99": 0.39,
+	})
+}
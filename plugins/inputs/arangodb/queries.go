@@ -0,0 +1,71 @@
+package arangodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/influxdata/telegraf"
+	"io/ioutil"
+	"net/url"
+)
+
+const currentQueriesPostfix = "/_api/query/current"
+const slowQueriesPostfix = "/_api/query/slow"
+
+// AQLQuery describes a single entry returned by /_api/query/current or
+// /_api/query/slow.
+type AQLQuery struct {
+	ID      string  `json:"id"`
+	Query   string  `json:"query"`
+	Started string  `json:"started"`
+	State   string  `json:"state"`
+	RunTime float64 `json:"runTime"`
+}
+
+// gatherQueries reports the currently running and recent slow AQL queries
+// for db as arangodb_query measurements.
+func (p *ArangoDB) gatherQueries(u url.URL, db string, acc telegraf.Accumulator) error {
+	if err := p.gatherAQLQueries(u, db, currentQueriesPostfix, "current", acc); err != nil {
+		acc.AddError(fmt.Errorf("error gathering current queries for %s/%s: %s", u, db, err))
+	}
+
+	if err := p.gatherAQLQueries(u, db, slowQueriesPostfix, "slow", acc); err != nil {
+		acc.AddError(fmt.Errorf("error gathering slow queries for %s/%s: %s", u, db, err))
+	}
+
+	return nil
+}
+
+func (p *ArangoDB) gatherAQLQueries(u url.URL, db, postfix, state string, acc telegraf.Accumulator) error {
+	resp, err := p.authedRequest(u, dbPath(db, postfix))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading query body: %s", err)
+	}
+
+	var queries []AQLQuery
+	if err := json.Unmarshal(body, &queries); err != nil {
+		return err
+	}
+
+	for _, query := range queries {
+		tags := map[string]string{
+			"url":      u.String(),
+			"database": db,
+			"state":    state,
+			"id":       query.ID,
+		}
+		fields := map[string]interface{}{
+			"run_time": query.RunTime,
+			"query":    query.Query,
+			"started":  query.Started,
+		}
+		acc.AddFields("arangodb_query", fields, tags)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package arangodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/influxdata/telegraf"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+)
+
+const loggerStatePostfix = "/_api/replication/logger-state"
+const applierStatePostfix = "/_api/replication/applier-state"
+
+// ReplicationLoggerState is the response of /_api/replication/logger-state.
+type ReplicationLoggerState struct {
+	State struct {
+		Running     bool   `json:"running"`
+		LastLogTick string `json:"lastLogTick"`
+		TotalEvents int64  `json:"totalEvents"`
+	} `json:"state"`
+}
+
+// ReplicationApplierState is the response of
+// /_api/replication/applier-state.
+type ReplicationApplierState struct {
+	State struct {
+		Running                     bool   `json:"running"`
+		LastAppliedContinuousTick   string `json:"lastAppliedContinuousTick"`
+		LastProcessedContinuousTick string `json:"lastProcessedContinuousTick"`
+		TotalEvents                 int64  `json:"totalEvents"`
+		TotalDocuments              int64  `json:"totalDocuments"`
+	} `json:"state"`
+}
+
+// gatherReplication reports the replication logger and applier state for
+// db as a single arangodb_replication measurement.
+func (p *ArangoDB) gatherReplication(u url.URL, db string, acc telegraf.Accumulator) error {
+	logger, err := p.replicationLoggerState(u, db)
+	if err != nil {
+		return fmt.Errorf("error gathering replication logger state for %s/%s: %s", u, db, err)
+	}
+
+	applier, err := p.replicationApplierState(u, db)
+	if err != nil {
+		return fmt.Errorf("error gathering replication applier state for %s/%s: %s", u, db, err)
+	}
+
+	tags := map[string]string{
+		"url":      u.String(),
+		"database": db,
+	}
+
+	fields := map[string]interface{}{
+		"logger_running":          logger.State.Running,
+		"logger_total_events":     logger.State.TotalEvents,
+		"applier_running":         applier.State.Running,
+		"applier_total_events":    applier.State.TotalEvents,
+		"applier_total_documents": applier.State.TotalDocuments,
+	}
+
+	if lag, ok := replicationLag(logger.State.LastLogTick, applier.State.LastAppliedContinuousTick); ok {
+		fields["lag"] = lag
+	}
+
+	acc.AddFields("arangodb_replication", fields, tags)
+
+	return nil
+}
+
+func (p *ArangoDB) replicationLoggerState(u url.URL, db string) (ReplicationLoggerState, error) {
+	state := ReplicationLoggerState{}
+
+	resp, err := p.authedRequest(u, dbPath(db, loggerStatePostfix))
+	if err != nil {
+		return state, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return state, fmt.Errorf("error reading logger-state body: %s", err)
+	}
+
+	err = json.Unmarshal(body, &state)
+	return state, err
+}
+
+func (p *ArangoDB) replicationApplierState(u url.URL, db string) (ReplicationApplierState, error) {
+	state := ReplicationApplierState{}
+
+	resp, err := p.authedRequest(u, dbPath(db, applierStatePostfix))
+	if err != nil {
+		return state, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return state, fmt.Errorf("error reading applier-state body: %s", err)
+	}
+
+	err = json.Unmarshal(body, &state)
+	return state, err
+}
+
+// replicationLag computes how far the applier is behind the logger, in log
+// tick units. Both ticks are decimal strings; if either is missing or
+// unparseable, ok is false.
+func replicationLag(loggerTick, applierTick string) (int64, bool) {
+	last, err := strconv.ParseInt(loggerTick, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	applied, err := strconv.ParseInt(applierTick, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return last - applied, true
+}